@@ -0,0 +1,47 @@
+package board
+
+import "context"
+
+// Job is a unit of work enqueued by Service.CreateTask for a runner worker
+// to pick up and process against genkit.
+type Job struct {
+	TaskID string
+}
+
+// Queue decouples task persistence from task processing: CreateTask
+// enqueues a Job and returns immediately, while pkg/board/runner workers
+// dequeue and drive the genkit call asynchronously.
+type Queue interface {
+	Enqueue(ctx context.Context, job Job) error
+	Dequeue(ctx context.Context) (Job, error)
+}
+
+// ChannelQueue is the default Queue, backed by an in-memory buffered
+// channel. It is durable only for the lifetime of the process; callers
+// that need jobs to survive a restart should provide their own Queue
+// implementation backed by a persistent broker.
+type ChannelQueue struct {
+	jobs chan Job
+}
+
+func NewChannelQueue(capacity int) *ChannelQueue {
+	return &ChannelQueue{jobs: make(chan Job, capacity)}
+}
+
+func (q *ChannelQueue) Enqueue(ctx context.Context, job Job) error {
+	select {
+	case q.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *ChannelQueue) Dequeue(ctx context.Context) (Job, error) {
+	select {
+	case job := <-q.jobs:
+		return job, nil
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
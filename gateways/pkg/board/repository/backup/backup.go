@@ -0,0 +1,79 @@
+// Package backup streams tasks out of and back into a repository.Repository
+// as newline-delimited JSON, so any backend (memory, sql, ...) can be
+// backed up and restored the same way.
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+)
+
+// Options configures a Backup call.
+type Options struct {
+	// Since, when non-zero, restricts the backup to tasks whose
+	// UpdatedAt is after this time, enabling incremental backups.
+	Since time.Time
+}
+
+// Backup writes every task in repo to w as newline-delimited JSON, one
+// Task per line, ordered by ID so the output is deterministic. It relies
+// only on Repository.ListTasks, so it works unmodified against any
+// backend.
+func Backup(ctx context.Context, repo repository.Repository, w io.Writer, opts Options) error {
+	tasks, err := repo.ListTasks(ctx)
+	if err != nil {
+		return fmt.Errorf("listing tasks: %w", err)
+	}
+
+	enc := json.NewEncoder(w)
+	for _, t := range tasks {
+		if !opts.Since.IsZero() && !t.UpdatedAt.After(opts.Since) {
+			continue
+		}
+		if err := enc.Encode(t); err != nil {
+			return fmt.Errorf("encoding task %s: %w", t.ID, err)
+		}
+	}
+	return nil
+}
+
+// Restore reads newline-delimited Task JSON from r and upserts each one
+// into repo: tasks that don't exist yet are created, tasks that already
+// exist are overwritten via UpdateTask, so Restore can be replayed
+// idempotently (e.g. to apply an incremental backup on top of a full one).
+func Restore(ctx context.Context, repo repository.Repository, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var t repository.Task
+		if err := json.Unmarshal(line, &t); err != nil {
+			return fmt.Errorf("decoding task: %w", err)
+		}
+
+		if _, err := repo.CreateTask(ctx, t); err != nil {
+			if !errors.Is(err, repository.ErrAlreadyExists) {
+				return fmt.Errorf("creating task %s: %w", t.ID, err)
+			}
+			if _, err := repo.UpdateTask(ctx, t); err != nil {
+				return fmt.Errorf("updating task %s: %w", t.ID, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading backup: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	dbsql "database/sql"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository/memory"
+	boardsql "gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository/sql"
+)
+
+func seed(t *testing.T, repo repository.Repository) {
+	t.Helper()
+	ctx := context.Background()
+	tasks := []repository.Task{
+		{ID: "task-1", Status: "OK", Messages: []repository.Message{repository.NewTextMessage(repository.RoleUser, "hi")}},
+		{ID: "task-2", Status: "PENDING", Messages: []repository.Message{repository.NewTextMessage(repository.RoleUser, "bye")}},
+	}
+	for _, task := range tasks {
+		if _, err := repo.CreateTask(ctx, task); err != nil {
+			t.Fatalf("seeding CreateTask(%s): %v", task.ID, err)
+		}
+	}
+}
+
+func TestBackupRestore_MemoryToSQLParity(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.NewRepository()
+	seed(t, memRepo)
+
+	var buf bytes.Buffer
+	if err := Backup(ctx, memRepo, &buf, Options{}); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+
+	db, err := dbsql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	defer db.Close()
+
+	sqlRepo := boardsql.NewRepository(db, boardsql.DialectSQLite)
+	if err := sqlRepo.Migrate(ctx); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	if err := Restore(ctx, sqlRepo, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	memTasks, err := memRepo.ListTasks(ctx)
+	if err != nil {
+		t.Fatalf("memory ListTasks: %v", err)
+	}
+	sqlTasks, err := sqlRepo.ListTasks(ctx)
+	if err != nil {
+		t.Fatalf("sql ListTasks: %v", err)
+	}
+
+	if len(memTasks) != len(sqlTasks) {
+		t.Fatalf("expected %d tasks restored, got %d", len(memTasks), len(sqlTasks))
+	}
+	for i := range memTasks {
+		if memTasks[i].ID != sqlTasks[i].ID || memTasks[i].Status != sqlTasks[i].Status {
+			t.Fatalf("task %d diverged: memory=%+v sql=%+v", i, memTasks[i], sqlTasks[i])
+		}
+		if len(memTasks[i].Messages) != len(sqlTasks[i].Messages) {
+			t.Fatalf("task %d message count diverged: memory=%d sql=%d", i, len(memTasks[i].Messages), len(sqlTasks[i].Messages))
+		}
+	}
+}
+
+func TestBackup_IncrementalOnlyIncludesUpdatedTasks(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository()
+	seed(t, repo)
+
+	cutoff := time.Now()
+
+	task, err := repo.GetTask(ctx, "task-2")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	task.Status = "OK"
+	if _, err := repo.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Backup(ctx, repo, &buf, Options{Since: cutoff}); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+
+	fresh := memory.NewRepository()
+	if err := Restore(ctx, fresh, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	restored, err := fresh.ListTasks(ctx)
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if len(restored) != 1 || restored[0].ID != "task-2" {
+		t.Fatalf("expected only task-2 in incremental backup, got %+v", restored)
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import "fmt"
+
+// Code identifies the class of failure a Repository operation produced, so
+// that transport layers can map it to a protocol-specific status without
+// string-matching error messages.
+type Code int
+
+const (
+	ErrUnknown Code = iota
+	ErrNotFound
+	ErrAlreadyExists
+	ErrValidationFailed
+	ErrInternal
+	ErrDeadlineExceeded
+	ErrNoPermission
+	ErrUnauthenticated
+	ErrConflict
+	ErrUnimplemented
+)
+
+// Error satisfies the error interface so that the Err* constants can be
+// used directly as errors.Is targets, e.g. errors.Is(err, repository.ErrNotFound).
+func (c Code) Error() string {
+	return c.String()
+}
+
+func (c Code) String() string {
+	switch c {
+	case ErrNotFound:
+		return "NOT_FOUND"
+	case ErrAlreadyExists:
+		return "ALREADY_EXISTS"
+	case ErrValidationFailed:
+		return "VALIDATION_FAILED"
+	case ErrInternal:
+		return "INTERNAL"
+	case ErrDeadlineExceeded:
+		return "DEADLINE_EXCEEDED"
+	case ErrNoPermission:
+		return "NO_PERMISSION"
+	case ErrUnauthenticated:
+		return "UNAUTHENTICATED"
+	case ErrConflict:
+		return "CONFLICT"
+	case ErrUnimplemented:
+		return "UNIMPLEMENTED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Error is the error type returned by Repository implementations. Callers
+// should use errors.Is against the sentinel Err* codes (Error implements
+// Is so that matching works without comparing the wrapped cause or
+// message), and errors.As to recover the Code, Message and Cause.
+type Error struct {
+	Code    Code
+	Message string
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		if e.Cause != nil {
+			return fmt.Sprintf("%s: %v", e.Code, e.Cause)
+		}
+		return e.Code.String()
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is lets errors.Is(err, repository.ErrNotFound) work by comparing codes
+// instead of requiring the target to be an *Error with the same fields.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(Code)
+	if !ok {
+		return false
+	}
+	return e.Code == code
+}
+
+func NewError(code Code, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, Cause: cause}
+}
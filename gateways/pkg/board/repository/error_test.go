@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestError_IsMatchesCode(t *testing.T) {
+	err := NewError(ErrNotFound, "task-1", nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected errors.Is to match ErrNotFound")
+	}
+	if errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected errors.Is not to match a different code")
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	cause := errors.New("driver failure")
+	err := NewError(ErrInternal, "querying task", cause)
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected errors.Is to find the wrapped cause")
+	}
+
+	var got *Error
+	if !errors.As(err, &got) {
+		t.Fatalf("expected errors.As to recover *Error")
+	}
+	if got.Code != ErrInternal || got.Message != "querying task" || got.Cause != cause {
+		t.Fatalf("unexpected error fields: %+v", got)
+	}
+}
+
+func TestCode_String(t *testing.T) {
+	cases := map[Code]string{
+		ErrNotFound:         "NOT_FOUND",
+		ErrAlreadyExists:    "ALREADY_EXISTS",
+		ErrValidationFailed: "VALIDATION_FAILED",
+		ErrInternal:         "INTERNAL",
+		ErrDeadlineExceeded: "DEADLINE_EXCEEDED",
+		ErrNoPermission:     "NO_PERMISSION",
+		ErrUnauthenticated:  "UNAUTHENTICATED",
+		ErrConflict:         "CONFLICT",
+		ErrUnimplemented:    "UNIMPLEMENTED",
+		Code(99):            "UNKNOWN",
+	}
+	for code, want := range cases {
+		if got := code.String(); got != want {
+			t.Fatalf("Code(%d).String() = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -3,11 +3,17 @@ package memory
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
 )
 
+func notFoundErr(id string) error {
+	return repository.NewError(repository.ErrNotFound, fmt.Sprintf("task with ID %s not found", id), nil)
+}
+
 type Repository struct {
 	mu    sync.RWMutex
 	tasks map[string]repository.Task
@@ -24,9 +30,11 @@ func (r *Repository) CreateTask(ctx context.Context, t repository.Task) (reposit
 	defer r.mu.Unlock()
 
 	if _, exists := r.tasks[t.ID]; exists {
-		return repository.Task{}, fmt.Errorf("task with ID %s already exists", t.ID)
+		return repository.Task{}, repository.NewError(repository.ErrAlreadyExists, fmt.Sprintf("task with ID %s already exists", t.ID), nil)
 	}
 
+	t.UpdatedAt = time.Now()
+	t.SchemaVersion = repository.CurrentSchemaVersion
 	r.tasks[t.ID] = t
 	return t, nil
 }
@@ -37,7 +45,7 @@ func (r *Repository) GetTask(ctx context.Context, id string) (repository.Task, e
 
 	t, exists := r.tasks[id]
 	if !exists {
-		return repository.Task{}, fmt.Errorf("task with ID %s not found", id)
+		return repository.Task{}, notFoundErr(id)
 	}
 
 	return t, nil
@@ -48,9 +56,11 @@ func (r *Repository) UpdateTask(ctx context.Context, t repository.Task) (reposit
 	defer r.mu.Unlock()
 
 	if _, exists := r.tasks[t.ID]; !exists {
-		return repository.Task{}, fmt.Errorf("task with ID %s not found", t.ID)
+		return repository.Task{}, notFoundErr(t.ID)
 	}
 
+	t.UpdatedAt = time.Now()
+	t.SchemaVersion = repository.CurrentSchemaVersion
 	r.tasks[t.ID] = t
 	return t, nil
 }
@@ -60,9 +70,22 @@ func (r *Repository) DeleteTask(ctx context.Context, id string) error {
 	defer r.mu.Unlock()
 
 	if _, exists := r.tasks[id]; !exists {
-		return fmt.Errorf("task with ID %s not found", id)
+		return notFoundErr(id)
 	}
 
 	delete(r.tasks, id)
 	return nil
 }
+
+func (r *Repository) ListTasks(ctx context.Context) ([]repository.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]repository.Task, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Role identifies who produced a Message in a conversation, so gateways
+// like genkit can reconstruct a proper multi-turn prompt instead of
+// guessing from an ad-hoc "user"/"assistant" string.
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+)
+
+// PartType discriminates the Part union.
+type PartType string
+
+const (
+	PartTypeText       PartType = "text"
+	PartTypeImage      PartType = "image"
+	PartTypeToolCall   PartType = "tool_call"
+	PartTypeToolResult PartType = "tool_result"
+)
+
+// Part is one piece of a Message's content. Exactly one of the
+// type-specific fields is populated, matching Type.
+type Part struct {
+	Type PartType `json:"type"`
+
+	// Text holds the content for PartTypeText.
+	Text string `json:"text,omitempty"`
+
+	// ImageRef holds a URI or storage key for PartTypeImage; the image
+	// bytes themselves are not inlined here.
+	ImageRef string `json:"image_ref,omitempty"`
+
+	ToolCall   *ToolCall   `json:"tool_call,omitempty"`
+	ToolResult *ToolResult `json:"tool_result,omitempty"`
+}
+
+// ToolCall is an assistant-initiated function call.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// ToolResult is the outcome of a ToolCall, linked back to it by CallID.
+type ToolResult struct {
+	CallID  string `json:"call_id"`
+	Content string `json:"content"`
+}
+
+func TextPart(text string) Part {
+	return Part{Type: PartTypeText, Text: text}
+}
+
+func ImagePart(ref string) Part {
+	return Part{Type: PartTypeImage, ImageRef: ref}
+}
+
+func ToolCallPart(call ToolCall) Part {
+	return Part{Type: PartTypeToolCall, ToolCall: &call}
+}
+
+func ToolResultPart(result ToolResult) Part {
+	return Part{Type: PartTypeToolResult, ToolResult: &result}
+}
+
+type Message struct {
+	Role  Role
+	Parts []Part
+}
+
+// NewTextMessage is a convenience constructor for the common case of a
+// single text part, which covers most user/assistant turns.
+func NewTextMessage(role Role, text string) Message {
+	return Message{Role: role, Parts: []Part{TextPart(text)}}
+}
+
+// legacyMessage is the pre-SchemaVersion-2 shape of Message, kept only so
+// UnmarshalJSON can upgrade data written by older versions of this
+// package on the fly.
+type legacyMessage struct {
+	Type    string
+	Content string
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	type alias Message
+	return json.Marshal(alias(m))
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type alias Message
+	var a alias
+	if err := json.Unmarshal(data, &a); err == nil && (len(a.Parts) > 0 || a.Role != "") {
+		*m = Message(a)
+		return nil
+	}
+
+	var legacy legacyMessage
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("unmarshaling message: %w", err)
+	}
+	*m = Message{
+		Role:  legacyRoleFromType(legacy.Type),
+		Parts: []Part{TextPart(legacy.Content)},
+	}
+	return nil
+}
+
+// UpgradeLegacyMessage converts a pre-SchemaVersion-2 {Type, Content}
+// message into the current Role/Parts shape. Backends that stored the
+// legacy columns directly (rather than relying on Message's JSON
+// marshaling) use this to backfill during Migrate.
+func UpgradeLegacyMessage(msgType, content string) Message {
+	return Message{
+		Role:  legacyRoleFromType(msgType),
+		Parts: []Part{TextPart(content)},
+	}
+}
+
+func legacyRoleFromType(t string) Role {
+	switch t {
+	case "assistant":
+		return RoleAssistant
+	case "system":
+		return RoleSystem
+	case "tool":
+		return RoleTool
+	default:
+		return RoleUser
+	}
+}
+
+// ValidateMessages rejects message histories that reference tool results
+// without a matching preceding tool call, which no genkit gateway could
+// make sense of.
+func ValidateMessages(messages []Message) error {
+	pendingCalls := map[string]bool{}
+
+	for i, msg := range messages {
+		for _, part := range msg.Parts {
+			switch part.Type {
+			case PartTypeToolCall:
+				if part.ToolCall == nil {
+					return NewError(ErrValidationFailed, fmt.Sprintf("message %d: tool_call part missing ToolCall", i), nil)
+				}
+				pendingCalls[part.ToolCall.ID] = true
+			case PartTypeToolResult:
+				if part.ToolResult == nil {
+					return NewError(ErrValidationFailed, fmt.Sprintf("message %d: tool_result part missing ToolResult", i), nil)
+				}
+				if !pendingCalls[part.ToolResult.CallID] {
+					return NewError(ErrValidationFailed, fmt.Sprintf("message %d: tool_result for call %s has no matching tool_call", i, part.ToolResult.CallID), nil)
+				}
+				delete(pendingCalls, part.ToolResult.CallID)
+			case PartTypeText, PartTypeImage:
+			default:
+				return NewError(ErrValidationFailed, fmt.Sprintf("message %d: unknown part type %q", i, part.Type), nil)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMessage_UnmarshalJSON_UpgradesLegacyShape(t *testing.T) {
+	var m Message
+	if err := json.Unmarshal([]byte(`{"Type":"assistant","Content":"hi there"}`), &m); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if m.Role != RoleAssistant {
+		t.Fatalf("expected RoleAssistant, got %q", m.Role)
+	}
+	if len(m.Parts) != 1 || m.Parts[0].Type != PartTypeText || m.Parts[0].Text != "hi there" {
+		t.Fatalf("unexpected parts: %+v", m.Parts)
+	}
+}
+
+func TestMessage_MarshalUnmarshalRoundTrips(t *testing.T) {
+	want := NewTextMessage(RoleUser, "hello")
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got Message
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Role != want.Role || len(got.Parts) != 1 || got.Parts[0] != want.Parts[0] {
+		t.Fatalf("round trip mismatch: want %+v, got %+v", want, got)
+	}
+}
+
+func TestValidateMessages_RejectsOrphanToolResult(t *testing.T) {
+	messages := []Message{
+		NewTextMessage(RoleUser, "what's the weather?"),
+		{Role: RoleTool, Parts: []Part{ToolResultPart(ToolResult{CallID: "call-1", Content: "sunny"})}},
+	}
+	if err := ValidateMessages(messages); err == nil {
+		t.Fatalf("expected validation error for orphan tool_result, got nil")
+	}
+}
+
+func TestValidateMessages_AcceptsMatchedToolCallAndResult(t *testing.T) {
+	messages := []Message{
+		NewTextMessage(RoleUser, "what's the weather?"),
+		{Role: RoleAssistant, Parts: []Part{ToolCallPart(ToolCall{ID: "call-1", Name: "get_weather", Arguments: json.RawMessage(`{}`)})}},
+		{Role: RoleTool, Parts: []Part{ToolResultPart(ToolResult{CallID: "call-1", Content: "sunny"})}},
+	}
+	if err := ValidateMessages(messages); err != nil {
+		t.Fatalf("expected no validation error, got %v", err)
+	}
+}
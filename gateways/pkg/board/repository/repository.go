@@ -1,16 +1,34 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// CurrentSchemaVersion is stamped onto every Task this package produces.
+// Bump it whenever Task or Message gain a breaking change, and branch on
+// the old value in the affected backend's migration path.
+const CurrentSchemaVersion = 2
 
 type Task struct {
 	ID       string
 	Messages []Message
 	Status   string
-}
 
-type Message struct {
-	Type    string
-	Content string
+	// SchemaVersion records which shape Messages was written in, so a
+	// backend can tell a pre-Parts Task apart from a current one when
+	// migrating data written by an older version of this package.
+	SchemaVersion int
+
+	// LeasedUntil is set by a runner worker while it owns this task, so a
+	// crashed worker's lease expires and the job can be reclaimed by
+	// another worker instead of being stuck forever.
+	LeasedUntil time.Time
+
+	// UpdatedAt is refreshed on every CreateTask/UpdateTask call, so
+	// pkg/board/repository/backup can do incremental backups by only
+	// exporting tasks updated after a given time.
+	UpdatedAt time.Time
 }
 
 type Repository interface {
@@ -18,4 +36,9 @@ type Repository interface {
 	GetTask(ctx context.Context, id string) (Task, error)
 	UpdateTask(ctx context.Context, task Task) (Task, error)
 	DeleteTask(ctx context.Context, id string) error
+
+	// ListTasks returns every task the repository holds. It backs
+	// pkg/board/repository/backup and is expected to work the same way
+	// regardless of backend.
+	ListTasks(ctx context.Context) ([]Task, error)
 }
@@ -0,0 +1,29 @@
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// rebind rewrites a query written with SQLite-style `?` placeholders into
+// the dialect this Repository was opened with. Postgres needs positional
+// `$1, $2, ...` placeholders; every other supported dialect uses `?`
+// as-is, so queries are always written with `?` and rebound here.
+func (r *Repository) rebind(query string) string {
+	if r.dialect != DialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,340 @@
+// Package sql is a database/sql-backed repository.Repository, so tasks
+// survive process restarts. It targets SQLite and Postgres through the
+// same code path; the only dialect-specific thing is parameter
+// placeholders (see placeholder.go).
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+)
+
+type Repository struct {
+	db      *dbsql.DB
+	dialect Dialect
+}
+
+func NewRepository(db *dbsql.DB, dialect Dialect) *Repository {
+	return &Repository{db: db, dialect: dialect}
+}
+
+// Migrate brings the schema up to the latest migration and backfills any
+// data an older version of this package left in the legacy type/content
+// message columns. It is safe to call on every startup: already-applied
+// migrations are skipped.
+func (r *Repository) Migrate(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+
+	applied := map[int]bool{}
+	rows, err := r.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("reading applied migrations: %w", err)
+	}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning applied migration: %w", err)
+		}
+		applied[v] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading applied migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("beginning migration %d: %w", m.version, err)
+		}
+		for _, stmt := range m.stmts {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("running migration %d: %w", m.version, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, r.rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), m.version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("committing migration %d: %w", m.version, err)
+		}
+	}
+
+	return r.migrateLegacyMessages(ctx)
+}
+
+// migrateLegacyMessages upgrades rows written before migration 2 (which
+// only had type/content) by deriving role/parts from them, so a task
+// written by an older version of this package reads back correctly
+// through the current Repository.
+func (r *Repository) migrateLegacyMessages(ctx context.Context) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT task_id, seq, type, content FROM messages WHERE parts = ''`)
+	if err != nil {
+		return fmt.Errorf("finding legacy messages: %w", err)
+	}
+	type legacyRow struct {
+		taskID  string
+		seq     int
+		msgType string
+		content string
+	}
+	var legacy []legacyRow
+	for rows.Next() {
+		var l legacyRow
+		if err := rows.Scan(&l.taskID, &l.seq, &l.msgType, &l.content); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning legacy message: %w", err)
+		}
+		legacy = append(legacy, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("finding legacy messages: %w", err)
+	}
+
+	for _, l := range legacy {
+		msg := repository.UpgradeLegacyMessage(l.msgType, l.content)
+
+		partsJSON, err := json.Marshal(msg.Parts)
+		if err != nil {
+			return fmt.Errorf("encoding migrated parts: %w", err)
+		}
+		if _, err := r.db.ExecContext(ctx, r.rebind(`UPDATE messages SET role = ?, parts = ? WHERE task_id = ? AND seq = ?`),
+			string(msg.Role), string(partsJSON), l.taskID, l.seq); err != nil {
+			return fmt.Errorf("backfilling message %s/%d: %w", l.taskID, l.seq, err)
+		}
+	}
+
+	if _, err := r.db.ExecContext(ctx, r.rebind(`UPDATE tasks SET schema_version = ? WHERE schema_version < ?`),
+		repository.CurrentSchemaVersion, repository.CurrentSchemaVersion); err != nil {
+		return fmt.Errorf("backfilling task schema_version: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CreateTask(ctx context.Context, t repository.Task) (repository.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "beginning transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := r.getTaskTx(ctx, tx, t.ID); err == nil {
+		return repository.Task{}, repository.NewError(repository.ErrAlreadyExists, fmt.Sprintf("task with ID %s already exists", t.ID), nil)
+	} else if code(err) != repository.ErrNotFound {
+		return repository.Task{}, err
+	}
+
+	t.UpdatedAt = time.Now()
+	t.SchemaVersion = repository.CurrentSchemaVersion
+	if err := r.insertTaskTx(ctx, tx, t); err != nil {
+		return repository.Task{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "committing transaction", err)
+	}
+	return t, nil
+}
+
+func (r *Repository) GetTask(ctx context.Context, id string) (repository.Task, error) {
+	return r.getTaskTx(ctx, r.db, id)
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, t repository.Task) (repository.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "beginning transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := r.getTaskTx(ctx, tx, t.ID); err != nil {
+		return repository.Task{}, err
+	}
+
+	t.UpdatedAt = time.Now()
+	t.SchemaVersion = repository.CurrentSchemaVersion
+	if _, err := tx.ExecContext(ctx, r.rebind(`UPDATE tasks SET status = ?, schema_version = ?, leased_until = ?, updated_at = ? WHERE id = ?`),
+		t.Status, t.SchemaVersion, nullTime(t.LeasedUntil), t.UpdatedAt, t.ID); err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "updating task", err)
+	}
+	if _, err := tx.ExecContext(ctx, r.rebind(`DELETE FROM messages WHERE task_id = ?`), t.ID); err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "clearing messages", err)
+	}
+	if err := r.insertMessagesTx(ctx, tx, t.ID, t.Messages); err != nil {
+		return repository.Task{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "committing transaction", err)
+	}
+	return t, nil
+}
+
+func (r *Repository) DeleteTask(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return repository.NewError(repository.ErrInternal, "beginning transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := r.getTaskTx(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, r.rebind(`DELETE FROM messages WHERE task_id = ?`), id); err != nil {
+		return repository.NewError(repository.ErrInternal, "deleting messages", err)
+	}
+	if _, err := tx.ExecContext(ctx, r.rebind(`DELETE FROM tasks WHERE id = ?`), id); err != nil {
+		return repository.NewError(repository.ErrInternal, "deleting task", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return repository.NewError(repository.ErrInternal, "committing transaction", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListTasks(ctx context.Context) ([]repository.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM tasks ORDER BY id`)
+	if err != nil {
+		return nil, repository.NewError(repository.ErrInternal, "listing tasks", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, repository.NewError(repository.ErrInternal, "scanning task id", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.NewError(repository.ErrInternal, "listing tasks", err)
+	}
+
+	tasks := make([]repository.Task, 0, len(ids))
+	for _, id := range ids {
+		t, err := r.getTaskTx(ctx, r.db, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// querier is satisfied by both *dbsql.DB and *dbsql.Tx, so read helpers
+// can run either standalone or as part of a larger transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*dbsql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *dbsql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (dbsql.Result, error)
+}
+
+func (r *Repository) getTaskTx(ctx context.Context, q querier, id string) (repository.Task, error) {
+	row := q.QueryRowContext(ctx, r.rebind(`SELECT id, status, schema_version, leased_until, updated_at FROM tasks WHERE id = ?`), id)
+
+	var t repository.Task
+	var leasedUntil dbsql.NullTime
+	if err := row.Scan(&t.ID, &t.Status, &t.SchemaVersion, &leasedUntil, &t.UpdatedAt); err != nil {
+		if err == dbsql.ErrNoRows {
+			return repository.Task{}, repository.NewError(repository.ErrNotFound, fmt.Sprintf("task with ID %s not found", id), nil)
+		}
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "loading task", err)
+	}
+	if leasedUntil.Valid {
+		t.LeasedUntil = leasedUntil.Time
+	}
+
+	rows, err := q.QueryContext(ctx, r.rebind(`SELECT role, parts FROM messages WHERE task_id = ? ORDER BY seq ASC`), id)
+	if err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "loading messages", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role string
+		var partsJSON string
+		if err := rows.Scan(&role, &partsJSON); err != nil {
+			return repository.Task{}, repository.NewError(repository.ErrInternal, "scanning message", err)
+		}
+		var parts []repository.Part
+		if err := json.Unmarshal([]byte(partsJSON), &parts); err != nil {
+			return repository.Task{}, repository.NewError(repository.ErrInternal, "decoding message parts", err)
+		}
+		t.Messages = append(t.Messages, repository.Message{Role: repository.Role(role), Parts: parts})
+	}
+	if err := rows.Err(); err != nil {
+		return repository.Task{}, repository.NewError(repository.ErrInternal, "loading messages", err)
+	}
+
+	return t, nil
+}
+
+func (r *Repository) insertTaskTx(ctx context.Context, tx *dbsql.Tx, t repository.Task) error {
+	if _, err := tx.ExecContext(ctx, r.rebind(`INSERT INTO tasks (id, status, schema_version, leased_until, updated_at) VALUES (?, ?, ?, ?, ?)`),
+		t.ID, t.Status, t.SchemaVersion, nullTime(t.LeasedUntil), t.UpdatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return repository.NewError(repository.ErrAlreadyExists, fmt.Sprintf("task with ID %s already exists", t.ID), err)
+		}
+		return repository.NewError(repository.ErrInternal, "inserting task", err)
+	}
+	return r.insertMessagesTx(ctx, tx, t.ID, t.Messages)
+}
+
+// isUniqueViolation reports whether err is a unique/primary-key constraint
+// violation from the underlying driver. database/sql doesn't expose a
+// driver-agnostic error type for this, so CreateTask's getTaskTx-then-insert
+// existence check is inherently racy between the check and the insert;
+// matching the driver's constraint-violation message is what lets a losing
+// concurrent CreateTask still come back as ErrAlreadyExists instead of
+// ErrInternal for both dialects this package targets.
+func isUniqueViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") || // SQLite
+		strings.Contains(msg, "duplicate key value violates unique constraint") // Postgres
+}
+
+func (r *Repository) insertMessagesTx(ctx context.Context, tx *dbsql.Tx, taskID string, messages []repository.Message) error {
+	for seq, m := range messages {
+		partsJSON, err := json.Marshal(m.Parts)
+		if err != nil {
+			return repository.NewError(repository.ErrValidationFailed, "encoding message parts", err)
+		}
+		if _, err := tx.ExecContext(ctx, r.rebind(`INSERT INTO messages (task_id, seq, type, content, role, parts) VALUES (?, ?, ?, ?, ?, ?)`),
+			taskID, seq, "", "", string(m.Role), string(partsJSON)); err != nil {
+			return repository.NewError(repository.ErrInternal, "inserting message", err)
+		}
+	}
+	return nil
+}
+
+func nullTime(t time.Time) dbsql.NullTime {
+	return dbsql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// code returns the repository.Code of err, or ErrInternal if err does not
+// wrap a *repository.Error.
+func code(err error) repository.Code {
+	var repoErr *repository.Error
+	if errors.As(err, &repoErr) {
+		return repoErr.Code
+	}
+	return repository.ErrInternal
+}
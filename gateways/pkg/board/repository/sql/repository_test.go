@@ -0,0 +1,158 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository/memory"
+)
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	db, err := dbsql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	r := NewRepository(db, DialectSQLite)
+	if err := r.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return r
+}
+
+func TestRepository_CreateGetUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepository(t)
+
+	task, err := r.CreateTask(ctx, repository.Task{
+		ID:     "task-1",
+		Status: "PENDING",
+		Messages: []repository.Message{
+			repository.NewTextMessage(repository.RoleUser, "hello"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.UpdatedAt.IsZero() {
+		t.Fatalf("expected UpdatedAt to be set")
+	}
+
+	if _, err := r.CreateTask(ctx, task); !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	got, err := r.GetTask(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Parts[0].Text != "hello" {
+		t.Fatalf("unexpected messages: %+v", got.Messages)
+	}
+
+	got.Status = "OK"
+	got.Messages = append(got.Messages, repository.NewTextMessage(repository.RoleAssistant, "hi"))
+	updated, err := r.UpdateTask(ctx, got)
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if len(updated.Messages) != 2 {
+		t.Fatalf("expected 2 messages after update, got %d", len(updated.Messages))
+	}
+
+	if err := r.DeleteTask(ctx, "task-1"); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := r.GetTask(ctx, "task-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+// TestRepository_ParityWithMemory drives the same sequence of operations
+// through the SQL and memory backends and checks they agree, so
+// pkg/board/repository/backup can treat them interchangeably.
+func TestRepository_ParityWithMemory(t *testing.T) {
+	ctx := context.Background()
+	sqlRepo := newTestRepository(t)
+	memRepo := memory.NewRepository()
+
+	input := repository.Task{
+		ID:     "task-parity",
+		Status: "PENDING",
+		Messages: []repository.Message{
+			repository.NewTextMessage(repository.RoleUser, "first"),
+			repository.NewTextMessage(repository.RoleAssistant, "second"),
+		},
+	}
+
+	if _, err := sqlRepo.CreateTask(ctx, input); err != nil {
+		t.Fatalf("sql CreateTask: %v", err)
+	}
+	if _, err := memRepo.CreateTask(ctx, input); err != nil {
+		t.Fatalf("memory CreateTask: %v", err)
+	}
+
+	sqlTask, err := sqlRepo.GetTask(ctx, input.ID)
+	if err != nil {
+		t.Fatalf("sql GetTask: %v", err)
+	}
+	memTask, err := memRepo.GetTask(ctx, input.ID)
+	if err != nil {
+		t.Fatalf("memory GetTask: %v", err)
+	}
+
+	if sqlTask.Status != memTask.Status || len(sqlTask.Messages) != len(memTask.Messages) {
+		t.Fatalf("backends diverged: sql=%+v memory=%+v", sqlTask, memTask)
+	}
+	for i := range sqlTask.Messages {
+		if !messagesEqual(sqlTask.Messages[i], memTask.Messages[i]) {
+			t.Fatalf("message %d diverged: sql=%+v memory=%+v", i, sqlTask.Messages[i], memTask.Messages[i])
+		}
+	}
+}
+
+// messagesEqual compares two Messages by value. Message cannot use ==
+// directly because it embeds a []Part slice.
+func messagesEqual(a, b repository.Message) bool {
+	return a.Role == b.Role && reflect.DeepEqual(a.Parts, b.Parts)
+}
+
+// TestRepository_InsertTaskTx_UniqueViolationMapsToAlreadyExists exercises
+// the race CreateTask's getTaskTx-then-insert existence check can lose: a
+// second insert for an ID that already exists should surface as
+// ErrAlreadyExists, not ErrInternal, so board.Service.CreateTask's
+// errors.Is(err, repository.ErrAlreadyExists) branch still fires.
+func TestRepository_InsertTaskTx_UniqueViolationMapsToAlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepository(t)
+
+	task := repository.Task{
+		ID:     "race-1",
+		Status: "PENDING",
+		Messages: []repository.Message{
+			repository.NewTextMessage(repository.RoleUser, "hi"),
+		},
+	}
+	if _, err := r.CreateTask(ctx, task); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.insertTaskTx(ctx, tx, task); !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists from the unique constraint, got %v", err)
+	}
+}
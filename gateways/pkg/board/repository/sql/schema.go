@@ -0,0 +1,54 @@
+package sql
+
+// Dialect selects the SQL variant to generate schema and queries for.
+// Both dialects share the same Repository code path; the tests in this
+// package run against SQLite only.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// migration is one forward-only, numbered schema change. Statements are
+// portable SQL (no AUTOINCREMENT/SERIAL, no dialect-specific types) so
+// they run unmodified against SQLite and Postgres; the only intentional
+// difference between dialects is parameter placeholders, handled by
+// rebind().
+type migration struct {
+	version int
+	stmts   []string
+}
+
+var migrations = []migration{
+	{
+		version: 1,
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS tasks (
+				id TEXT PRIMARY KEY,
+				status TEXT NOT NULL,
+				leased_until TIMESTAMP,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS messages (
+				task_id TEXT NOT NULL REFERENCES tasks(id),
+				seq INTEGER NOT NULL,
+				type TEXT NOT NULL,
+				content TEXT NOT NULL,
+				PRIMARY KEY (task_id, seq)
+			)`,
+		},
+	},
+	{
+		// v2 introduces Task.SchemaVersion and the Role/Parts message
+		// shape. type/content are kept (not dropped) so a rollback can
+		// still read them; Repository only writes role/parts going
+		// forward, and migrateLegacyMessages backfills existing rows.
+		version: 2,
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE messages ADD COLUMN role TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE messages ADD COLUMN parts TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+}
@@ -0,0 +1,77 @@
+// Package status converts repository.Code values into transport-specific
+// status representations, so a single interceptor/middleware can translate
+// any repository.Error into the right gRPC or HTTP response.
+package status
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+)
+
+// GRPCCode maps a repository.Code to the equivalent google.golang.org/grpc/codes.Code.
+func GRPCCode(code repository.Code) codes.Code {
+	switch code {
+	case repository.ErrNotFound:
+		return codes.NotFound
+	case repository.ErrAlreadyExists:
+		return codes.AlreadyExists
+	case repository.ErrValidationFailed:
+		return codes.InvalidArgument
+	case repository.ErrDeadlineExceeded:
+		return codes.DeadlineExceeded
+	case repository.ErrNoPermission:
+		return codes.PermissionDenied
+	case repository.ErrUnauthenticated:
+		return codes.Unauthenticated
+	case repository.ErrConflict:
+		return codes.Aborted
+	case repository.ErrUnimplemented:
+		return codes.Unimplemented
+	case repository.ErrInternal:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
+
+// HTTPStatus maps a repository.Code to the equivalent net/http status code.
+func HTTPStatus(code repository.Code) int {
+	switch code {
+	case repository.ErrNotFound:
+		return http.StatusNotFound
+	case repository.ErrAlreadyExists:
+		return http.StatusConflict
+	case repository.ErrValidationFailed:
+		return http.StatusBadRequest
+	case repository.ErrDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case repository.ErrNoPermission:
+		return http.StatusForbidden
+	case repository.ErrUnauthenticated:
+		return http.StatusUnauthorized
+	case repository.ErrConflict:
+		return http.StatusConflict
+	case repository.ErrUnimplemented:
+		return http.StatusNotImplemented
+	case repository.ErrInternal:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FromError extracts the repository.Code from err, defaulting to
+// ErrInternal when err does not wrap a *repository.Error. It is meant for
+// transport layers that only see the generic error returned by a Service
+// method and need a status to respond with.
+func FromError(err error) repository.Code {
+	var repoErr *repository.Error
+	if errors.As(err, &repoErr) {
+		return repoErr.Code
+	}
+	return repository.ErrInternal
+}
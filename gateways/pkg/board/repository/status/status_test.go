@@ -0,0 +1,62 @@
+package status
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+)
+
+func TestGRPCCode(t *testing.T) {
+	cases := map[repository.Code]codes.Code{
+		repository.ErrNotFound:         codes.NotFound,
+		repository.ErrAlreadyExists:    codes.AlreadyExists,
+		repository.ErrValidationFailed: codes.InvalidArgument,
+		repository.ErrDeadlineExceeded: codes.DeadlineExceeded,
+		repository.ErrNoPermission:     codes.PermissionDenied,
+		repository.ErrUnauthenticated:  codes.Unauthenticated,
+		repository.ErrConflict:         codes.Aborted,
+		repository.ErrUnimplemented:    codes.Unimplemented,
+		repository.ErrInternal:         codes.Internal,
+		repository.ErrUnknown:          codes.Unknown,
+	}
+	for code, want := range cases {
+		if got := GRPCCode(code); got != want {
+			t.Fatalf("GRPCCode(%v) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := map[repository.Code]int{
+		repository.ErrNotFound:         http.StatusNotFound,
+		repository.ErrAlreadyExists:    http.StatusConflict,
+		repository.ErrValidationFailed: http.StatusBadRequest,
+		repository.ErrDeadlineExceeded: http.StatusGatewayTimeout,
+		repository.ErrNoPermission:     http.StatusForbidden,
+		repository.ErrUnauthenticated:  http.StatusUnauthorized,
+		repository.ErrConflict:         http.StatusConflict,
+		repository.ErrUnimplemented:    http.StatusNotImplemented,
+		repository.ErrInternal:         http.StatusInternalServerError,
+		repository.ErrUnknown:          http.StatusInternalServerError,
+	}
+	for code, want := range cases {
+		if got := HTTPStatus(code); got != want {
+			t.Fatalf("HTTPStatus(%v) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestFromError(t *testing.T) {
+	repoErr := repository.NewError(repository.ErrConflict, "task-1", nil)
+	if got := FromError(repoErr); got != repository.ErrConflict {
+		t.Fatalf("FromError(repoErr) = %v, want %v", got, repository.ErrConflict)
+	}
+
+	if got := FromError(errors.New("plain error")); got != repository.ErrInternal {
+		t.Fatalf("FromError(plain) = %v, want %v", got, repository.ErrInternal)
+	}
+}
@@ -0,0 +1,222 @@
+// Package runner drives the async half of task processing: it dequeues
+// board.Job values enqueued by board.Service.CreateTask, calls genkit, and
+// writes the result back through the repository.
+package runner
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board"
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/genkit"
+)
+
+const (
+	StatusOK    = "OK"
+	StatusError = "ERROR"
+)
+
+// genkitClient is the subset of *genkit.Service the runner depends on, so
+// tests can substitute a fake that simulates failures without standing up
+// a real genkit backend.
+type genkitClient interface {
+	SendRequest(ctx context.Context, req *genkit.Request) (*genkit.Response, error)
+}
+
+type Config struct {
+	// Concurrency is how many workers poll the queue concurrently.
+	Concurrency int
+	// MaxAttempts bounds retries per job before the task is marked ERROR.
+	MaxAttempts int
+	// JobTimeout bounds a single genkit call.
+	JobTimeout time.Duration
+	// LeaseDuration is how long a worker holds a task before its lease
+	// expires and another worker is allowed to reclaim it.
+	LeaseDuration time.Duration
+	// BackoffBase is the unit the exponential backoff between attempts is
+	// scaled from (attempt 1 waits BackoffBase, attempt 2 waits 2x, ...).
+	BackoffBase time.Duration
+	// ReclaimInterval is how often the reclaim sweep runs, looking for
+	// Pending tasks whose LeasedUntil has expired so it can re-enqueue
+	// them for another worker to pick up.
+	ReclaimInterval time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Concurrency:     1,
+		MaxAttempts:     3,
+		JobTimeout:      30 * time.Second,
+		LeaseDuration:   time.Minute,
+		BackoffBase:     500 * time.Millisecond,
+		ReclaimInterval: time.Minute,
+	}
+}
+
+// Runner owns a pool of workers that dequeue board.Job values and process
+// them against genkit.
+type Runner struct {
+	repo      repository.Repository
+	queue     board.Queue
+	genkitSvc genkitClient
+	cfg       Config
+}
+
+func New(repo repository.Repository, queue board.Queue, genkitSvc *genkit.Service, cfg Config) *Runner {
+	return &Runner{repo: repo, queue: queue, genkitSvc: genkitSvc, cfg: cfg}
+}
+
+// Run starts cfg.Concurrency workers plus the lease-reclaim sweep, and
+// blocks until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	concurrency := r.cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			r.worker(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.reclaimLoop(ctx)
+	}()
+
+	wg.Wait()
+}
+
+// reclaimLoop periodically re-enqueues Pending tasks whose lease has
+// expired, so a worker that crashed mid-job (and so never reached the
+// OK/ERROR UpdateTask) doesn't strand its task forever.
+func (r *Runner) reclaimLoop(ctx context.Context) {
+	interval := r.cfg.ReclaimInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reclaimExpiredLeases(ctx)
+		}
+	}
+}
+
+func (r *Runner) reclaimExpiredLeases(ctx context.Context) {
+	tasks, err := r.repo.ListTasks(ctx)
+	if err != nil {
+		slog.Error("error listing tasks for lease reclaim", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, task := range tasks {
+		if task.Status != board.StatusPending || task.LeasedUntil.IsZero() || task.LeasedUntil.After(now) {
+			continue
+		}
+		if err := r.queue.Enqueue(ctx, board.Job{TaskID: task.ID}); err != nil {
+			slog.Error("error reclaiming expired lease", "task_id", task.ID, "error", err)
+			continue
+		}
+		slog.Info("reclaimed task with expired lease", "task_id", task.ID)
+	}
+}
+
+func (r *Runner) worker(ctx context.Context) {
+	for {
+		job, err := r.queue.Dequeue(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return
+			}
+			slog.Error("error dequeuing job", "error", err)
+			continue
+		}
+		r.process(ctx, job)
+	}
+}
+
+// process owns a job end to end: it leases the task, calls genkit with
+// exponential-backoff retries, and persists the final OK/ERROR status.
+// The lease is only renewed for as long as a worker is alive, so if this
+// worker crashes mid-job the task is left Pending with an expired
+// LeasedUntil; reclaimLoop is what actually notices that and re-enqueues
+// it, giving at-least-once processing.
+func (r *Runner) process(ctx context.Context, job board.Job) {
+	task, err := r.repo.GetTask(ctx, job.TaskID)
+	if err != nil {
+		slog.Error("error loading task for job", "task_id", job.TaskID, "error", err)
+		return
+	}
+	if len(task.Messages) == 0 {
+		slog.Error("task has no messages", "task_id", job.TaskID)
+		return
+	}
+
+	maxAttempts := r.cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		task.LeasedUntil = time.Now().Add(r.cfg.LeaseDuration)
+		if task, err = r.repo.UpdateTask(ctx, task); err != nil {
+			slog.Error("error extending task lease", "task_id", job.TaskID, "error", err)
+			return
+		}
+
+		jobCtx, cancel := context.WithTimeout(ctx, r.cfg.JobTimeout)
+		res, err := r.genkitSvc.SendRequest(jobCtx, &genkit.Request{
+			Data: genkit.RequestData{
+				SessionKey: task.ID,
+				// Messages carries the full conversation, not just the
+				// latest turn, so genkit can see prior tool calls/results
+				// and system prompts instead of a single flattened string.
+				Messages: task.Messages,
+			},
+		})
+		cancel()
+
+		if err == nil {
+			task.Messages = append(task.Messages, repository.NewTextMessage(repository.RoleAssistant, res.Result))
+			task.Status = StatusOK
+			if _, err := r.repo.UpdateTask(ctx, task); err != nil {
+				slog.Error("error persisting task result", "task_id", job.TaskID, "error", err)
+			}
+			return
+		}
+
+		lastErr = err
+		slog.Error("genkit request failed", "task_id", job.TaskID, "attempt", attempt, "error", err)
+		if attempt < maxAttempts {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(1<<(attempt-1)) * r.cfg.BackoffBase):
+			}
+		}
+	}
+
+	slog.Error("task exhausted retries", "task_id", job.TaskID, "error", lastErr)
+	task.Status = StatusError
+	if _, err := r.repo.UpdateTask(ctx, task); err != nil {
+		slog.Error("error persisting task failure", "task_id", job.TaskID, "error", err)
+	}
+}
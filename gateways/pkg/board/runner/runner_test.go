@@ -0,0 +1,271 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board"
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository/memory"
+	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/genkit"
+)
+
+type fakeGenkit struct {
+	mu        sync.Mutex
+	failUntil map[string]int
+	attempts  map[string]int
+	order     []string
+}
+
+func newFakeGenkit() *fakeGenkit {
+	return &fakeGenkit{failUntil: map[string]int{}, attempts: map[string]int{}}
+}
+
+func (f *fakeGenkit) SendRequest(ctx context.Context, req *genkit.Request) (*genkit.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := req.Data.SessionKey
+	f.attempts[key]++
+	f.order = append(f.order, key)
+
+	if f.attempts[key] <= f.failUntil[key] {
+		return nil, errors.New("simulated genkit failure")
+	}
+	last := req.Data.Messages[len(req.Data.Messages)-1]
+	return &genkit.Response{Result: "reply to " + last.Parts[0].Text}, nil
+}
+
+func newTestTask(t *testing.T, repo repository.Repository, id, content string) {
+	t.Helper()
+	_, err := repo.CreateTask(context.Background(), repository.Task{
+		ID:     id,
+		Status: board.StatusPending,
+		Messages: []repository.Message{
+			repository.NewTextMessage(repository.RoleUser, content),
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask(%s): %v", id, err)
+	}
+}
+
+func TestRunner_ProcessesJobInOrder(t *testing.T) {
+	repo := memory.NewRepository()
+	queue := board.NewChannelQueue(4)
+	fake := newFakeGenkit()
+
+	ids := []string{"task-1", "task-2", "task-3"}
+	for _, id := range ids {
+		newTestTask(t, repo, id, "hello "+id)
+	}
+
+	r := &Runner{repo: repo, queue: queue, genkitSvc: fake, cfg: DefaultConfig()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.worker(ctx)
+
+	for _, id := range ids {
+		if err := queue.Enqueue(ctx, board.Job{TaskID: id}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	for _, id := range ids {
+		waitForStatus(t, repo, id, runnerStatuses()...)
+	}
+	cancel()
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.order) != len(ids) {
+		t.Fatalf("expected %d genkit calls, got %d", len(ids), len(fake.order))
+	}
+	for i, id := range ids {
+		if fake.order[i] != id {
+			t.Fatalf("expected job %d to be %s, got %s", i, id, fake.order[i])
+		}
+	}
+}
+
+func TestRunner_RetriesOnFailureThenSucceeds(t *testing.T) {
+	repo := memory.NewRepository()
+	queue := board.NewChannelQueue(1)
+	fake := newFakeGenkit()
+	fake.failUntil["flaky"] = 2 // fails twice, succeeds on the 3rd attempt
+
+	newTestTask(t, repo, "flaky", "hi")
+
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 3
+	cfg.BackoffBase = time.Millisecond
+
+	r := &Runner{repo: repo, queue: queue, genkitSvc: fake, cfg: cfg}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.worker(ctx)
+
+	if err := queue.Enqueue(ctx, board.Job{TaskID: "flaky"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	task := waitForStatus(t, repo, "flaky", StatusOK, StatusError)
+	if task.Status != StatusOK {
+		t.Fatalf("expected task to eventually succeed, got status %s", task.Status)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.attempts["flaky"] != 3 {
+		t.Fatalf("expected 3 attempts, got %d", fake.attempts["flaky"])
+	}
+}
+
+func TestRunner_MarksTaskErrorAfterExhaustingRetries(t *testing.T) {
+	repo := memory.NewRepository()
+	queue := board.NewChannelQueue(1)
+	fake := newFakeGenkit()
+	fake.failUntil["doomed"] = 99
+
+	newTestTask(t, repo, "doomed", "hi")
+
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 2
+	cfg.BackoffBase = time.Millisecond
+
+	r := &Runner{repo: repo, queue: queue, genkitSvc: fake, cfg: cfg}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.worker(ctx)
+
+	if err := queue.Enqueue(ctx, board.Job{TaskID: "doomed"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	task := waitForStatus(t, repo, "doomed", StatusOK, StatusError)
+	if task.Status != StatusError {
+		t.Fatalf("expected task to be marked ERROR, got %s", task.Status)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.attempts["doomed"] != cfg.MaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", cfg.MaxAttempts, fake.attempts["doomed"])
+	}
+}
+
+func TestRunner_AtLeastOnceViaReenqueue(t *testing.T) {
+	repo := memory.NewRepository()
+	queue := board.NewChannelQueue(1)
+	fake := newFakeGenkit()
+
+	newTestTask(t, repo, "redelivered", "hi")
+
+	var calls int32
+	cfg := DefaultConfig()
+	r := &Runner{repo: repo, queue: queue, genkitSvc: fake, cfg: cfg}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.worker(ctx)
+
+	// Simulate a crashed worker by redelivering the same job twice, as an
+	// at-least-once queue would after a lease expiry.
+	for i := 0; i < 2; i++ {
+		if err := queue.Enqueue(ctx, board.Job{TaskID: "redelivered"}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		fake.mu.Lock()
+		attempts := fake.attempts["redelivered"]
+		fake.mu.Unlock()
+		if attempts >= 2 {
+			atomic.AddInt32(&calls, int32(attempts))
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected the job to be processed at least twice, got %d", attempts)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestRunner_ReclaimExpiredLeases(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository()
+	queue := board.NewChannelQueue(2)
+	r := &Runner{repo: repo, queue: queue, genkitSvc: newFakeGenkit(), cfg: DefaultConfig()}
+
+	newTestTask(t, repo, "expired-lease", "hi")
+	expired, err := repo.GetTask(ctx, "expired-lease")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	expired.LeasedUntil = time.Now().Add(-time.Minute)
+	if _, err := repo.UpdateTask(ctx, expired); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	newTestTask(t, repo, "live-lease", "hi")
+	live, err := repo.GetTask(ctx, "live-lease")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	live.LeasedUntil = time.Now().Add(time.Hour)
+	if _, err := repo.UpdateTask(ctx, live); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	r.reclaimExpiredLeases(ctx)
+
+	dequeueCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	job, err := queue.Dequeue(dequeueCtx)
+	if err != nil {
+		t.Fatalf("expected the expired-lease task to be reclaimed onto the queue, got: %v", err)
+	}
+	if job.TaskID != "expired-lease" {
+		t.Fatalf("expected expired-lease to be reclaimed, got %s", job.TaskID)
+	}
+
+	emptyCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := queue.Dequeue(emptyCtx); err == nil {
+		t.Fatalf("expected only the expired-lease task to be reclaimed, but the queue had another job")
+	}
+}
+
+func runnerStatuses() []string {
+	return []string{StatusOK, StatusError}
+}
+
+func waitForStatus(t *testing.T, repo repository.Repository, id string, want ...string) repository.Task {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		task, err := repo.GetTask(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetTask(%s): %v", id, err)
+		}
+		for _, status := range want {
+			if task.Status == status {
+				return task
+			}
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for task %s to reach one of %v, last status %s", id, want, task.Status)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -3,16 +3,23 @@ package board
 import (
 	"context"
 	"errors"
-	"log/slog"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/board/repository"
-	"gitlab.skypicker.com/platform/experimental/agents/gateways/pkg/genkit"
 )
 
+// StatusPending is set on a Task by CreateTask as soon as it is persisted
+// and its job enqueued, before any runner worker has picked it up.
+const StatusPending = "PENDING"
+
+// pollInterval is how often WatchTask re-reads the task while waiting for
+// a runner worker to move it out of StatusPending.
+const pollInterval = 200 * time.Millisecond
+
 type Service struct {
-	repo      repository.Repository
-	genkitSvc *genkit.Service
+	repo  repository.Repository
+	queue Queue
 }
 
 type Request struct {
@@ -22,10 +29,14 @@ type Response struct {
 	Task repository.Task
 }
 
-func NewService(repo repository.Repository, genkitSvc *genkit.Service) *Service {
-	return &Service{repo: repo, genkitSvc: genkitSvc}
+func NewService(repo repository.Repository, queue Queue) *Service {
+	return &Service{repo: repo, queue: queue}
 }
 
+// CreateTask persists the task as StatusPending and enqueues it for a
+// runner worker to process against genkit, returning as soon as the task
+// is durably stored rather than waiting on the model call. Use GetTask or
+// WatchTask to observe the resulting status transition.
 func (s *Service) CreateTask(ctx context.Context, req Request) (Response, error) {
 	if req.Task.ID == "" {
 		id, err := uuid.NewV4()
@@ -34,37 +45,36 @@ func (s *Service) CreateTask(ctx context.Context, req Request) (Response, error)
 		}
 		req.Task.ID = id.String()
 	}
+	if err := repository.ValidateMessages(req.Task.Messages); err != nil {
+		return Response{}, err
+	}
+	req.Task.Status = StatusPending
+	req.Task.SchemaVersion = repository.CurrentSchemaVersion
 	task, err := s.repo.CreateTask(ctx, req.Task)
 	if err != nil {
+		if !errors.Is(err, repository.ErrAlreadyExists) {
+			return Response{}, err
+		}
 		task, err = s.repo.GetTask(ctx, req.Task.ID)
 		if err != nil {
 			return Response{}, err
 		}
-		task.Messages = append(task.Messages, repository.Message{
-			Content: req.Task.Messages[len(req.Task.Messages)-1].Content,
-			Type:    "user",
-		})
+		if len(req.Task.Messages) == 0 {
+			return Response{}, repository.NewError(repository.ErrValidationFailed, "task has no messages", nil)
+		}
+		task.Messages = append(task.Messages, req.Task.Messages[len(req.Task.Messages)-1])
+		if err := repository.ValidateMessages(task.Messages); err != nil {
+			return Response{}, err
+		}
+		task.Status = StatusPending
+		if task, err = s.repo.UpdateTask(ctx, task); err != nil {
+			return Response{}, err
+		}
 	}
 	if len(task.Messages) == 0 {
-		return Response{}, errors.New("task has no messages")
-	}
-	res, err := s.genkitSvc.SendRequest(ctx, &genkit.Request{
-		Data: genkit.RequestData{
-			SessionKey: task.ID,
-			Message:    task.Messages[len(task.Messages)-1].Content,
-		},
-	})
-	if err != nil {
-		slog.Error("error sending request to genkit", "error", err)
-		task.Status = "ERROR"
-	} else {
-		task.Messages = append(task.Messages, repository.Message{
-			Content: res.Result,
-			Type:    "assistant",
-		})
-		task.Status = "OK"
+		return Response{}, repository.NewError(repository.ErrValidationFailed, "task has no messages", nil)
 	}
-	if task, err = s.repo.UpdateTask(ctx, task); err != nil {
+	if err := s.queue.Enqueue(ctx, Job{TaskID: task.ID}); err != nil {
 		return Response{}, err
 	}
 
@@ -72,3 +82,48 @@ func (s *Service) CreateTask(ctx context.Context, req Request) (Response, error)
 		Task: task,
 	}, nil
 }
+
+func (s *Service) GetTask(ctx context.Context, id string) (Response, error) {
+	task, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Task: task}, nil
+}
+
+// WatchTask polls the repository until the task leaves StatusPending, or
+// ctx is cancelled. It gives callers a way to wait for the runner's job
+// to finish without building a push-based notification path.
+func (s *Service) WatchTask(ctx context.Context, id string) (<-chan Response, <-chan error) {
+	updates := make(chan Response, 1)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(updates)
+		defer close(errs)
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			task, err := s.repo.GetTask(ctx, id)
+			if err != nil {
+				errs <- err
+				return
+			}
+			updates <- Response{Task: task}
+			if task.Status != StatusPending {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return updates, errs
+}